@@ -0,0 +1,270 @@
+// Package cbor decodes the CBOR log stream produced by ilog's EncoderCBOR (see
+// ilog.implLoggerConfig.AsBinary) back into human-readable JSON.
+package cbor
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// Decoder reads a sequence of CBOR-encoded values from a stream, one per ilog log event.
+type Decoder struct {
+	r *bufio.Reader
+}
+
+// NewDecoder returns a Decoder reading CBOR values from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads and returns the next CBOR value as a Go value suitable for json.Marshal
+// (map[string]interface{}, []interface{}, string, []byte, uint64, int64, float64, bool, or nil).
+// It returns io.EOF once the stream is exhausted.
+func (d *Decoder) Decode() (interface{}, error) {
+	head, err := d.r.ReadByte()
+	if err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return d.decodeValue(head)
+}
+
+//nolint:cyclop
+func (d *Decoder) decodeValue(head byte) (interface{}, error) {
+	major := head >> 5
+	info := head & 0x1F
+
+	switch major {
+	case 0: // unsigned int
+		n, err := d.readArg(info)
+		if err != nil {
+			return nil, fmt.Errorf("cbor: read unsigned: %w", err)
+		}
+		return n, nil
+	case 1: // negative int
+		n, err := d.readArg(info)
+		if err != nil {
+			return nil, fmt.Errorf("cbor: read negative: %w", err)
+		}
+		return -1 - int64(n), nil //nolint:gosec
+	case 2: // byte string
+		b, err := d.readString(info)
+		if err != nil {
+			return nil, fmt.Errorf("cbor: read byte string: %w", err)
+		}
+		return b, nil
+	case 3: // text string
+		s, err := d.readString(info)
+		if err != nil {
+			return nil, fmt.Errorf("cbor: read text string: %w", err)
+		}
+		return string(s), nil
+	case 4: // array
+		return d.decodeArray(info)
+	case 5: // map
+		return d.decodeMap(info)
+	case 7: // floats / simple values
+		return d.decodeSimple(info)
+	default:
+		return nil, fmt.Errorf("cbor: unsupported major type %d", major)
+	}
+}
+
+func (d *Decoder) readArg(info byte) (uint64, error) {
+	switch {
+	case info < 24:
+		return uint64(info), nil
+	case info == 24:
+		b, err := d.r.ReadByte()
+		return uint64(b), err //nolint:wrapcheck
+	case info == 25:
+		buf := make([]byte, 2)
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return 0, err //nolint:wrapcheck
+		}
+		return uint64(buf[0])<<8 | uint64(buf[1]), nil
+	case info == 26:
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return 0, err //nolint:wrapcheck
+		}
+		return uint64(buf[0])<<24 | uint64(buf[1])<<16 | uint64(buf[2])<<8 | uint64(buf[3]), nil
+	case info == 27:
+		buf := make([]byte, 8)
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return 0, err //nolint:wrapcheck
+		}
+		var n uint64
+		for _, b := range buf {
+			n = n<<8 | uint64(b)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("cbor: unsupported argument encoding %d", info)
+	}
+}
+
+func (d *Decoder) readString(info byte) ([]byte, error) {
+	n, err := d.readArg(info)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return nil, err //nolint:wrapcheck
+	}
+	return buf, nil
+}
+
+func (d *Decoder) decodeArray(info byte) (interface{}, error) {
+	if info == cborAdditionalIndefinite {
+		var items []interface{}
+		for {
+			head, err := d.r.ReadByte()
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+			if head == cborBreak {
+				return items, nil
+			}
+			v, err := d.decodeValue(head)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, v)
+		}
+	}
+
+	n, err := d.readArg(info)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]interface{}, 0, n)
+	for i := uint64(0); i < n; i++ {
+		head, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		v, err := d.decodeValue(head)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+func (d *Decoder) decodeMap(info byte) (interface{}, error) {
+	m := map[string]interface{}{}
+
+	readPair := func() (done bool, err error) {
+		keyHead, err := d.r.ReadByte()
+		if err != nil {
+			return false, err
+		}
+		if keyHead == cborBreak {
+			return true, nil
+		}
+		key, err := d.decodeValue(keyHead)
+		if err != nil {
+			return false, err
+		}
+		valueHead, err := d.r.ReadByte()
+		if err != nil {
+			return false, err
+		}
+		value, err := d.decodeValue(valueHead)
+		if err != nil {
+			return false, err
+		}
+		if k, ok := key.(string); ok {
+			m[k] = value
+		}
+		return false, nil
+	}
+
+	if info == cborAdditionalIndefinite {
+		for {
+			done, err := readPair()
+			if err != nil {
+				return nil, err //nolint:wrapcheck
+			}
+			if done {
+				return m, nil
+			}
+		}
+	}
+
+	n, err := d.readArg(info)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < n; i++ {
+		if _, err := readPair(); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+	}
+	return m, nil
+}
+
+func (d *Decoder) decodeSimple(info byte) (interface{}, error) {
+	switch info {
+	case cborSimpleFalse:
+		return false, nil
+	case cborSimpleTrue:
+		return true, nil
+	case cborSimpleNull:
+		return nil, nil
+	case cborSimpleFloat32:
+		buf := make([]byte, 4)
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		bits := uint32(buf[0])<<24 | uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3])
+		return float64(math.Float32frombits(bits)), nil
+	case cborSimpleFloat64:
+		buf := make([]byte, 8)
+		if _, err := io.ReadFull(d.r, buf); err != nil {
+			return nil, err //nolint:wrapcheck
+		}
+		var bits uint64
+		for _, b := range buf {
+			bits = bits<<8 | uint64(b)
+		}
+		return math.Float64frombits(bits), nil
+	default:
+		return nil, fmt.Errorf("cbor: unsupported simple value %d", info)
+	}
+}
+
+const (
+	cborAdditionalIndefinite = 31
+	cborSimpleFalse          = 20
+	cborSimpleTrue           = 21
+	cborSimpleNull           = 22
+	cborSimpleFloat32        = 26
+	cborSimpleFloat64        = 27
+	cborBreak                = 0xFF
+)
+
+// DecodeTo reads a stream of CBOR-encoded ilog events from r and writes them to w as
+// newline-delimited JSON, one log event per line — the inverse of ilog's EncoderCBOR.
+func DecodeTo(r io.Reader, w io.Writer) error {
+	dec := NewDecoder(r)
+	enc := json.NewEncoder(w)
+
+	for {
+		v, err := dec.Decode()
+		if err != nil {
+			if err == io.EOF { //nolint:errorlint
+				return nil
+			}
+			return fmt.Errorf("cbor: Decode: %w", err)
+		}
+		if err := enc.Encode(v); err != nil {
+			return fmt.Errorf("cbor: Encode: %w", err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package ilog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+)
+
+// ErrAsyncWriterClosed is returned by AsyncWriter.Write once the writer has been closed.
+var ErrAsyncWriterClosed = errors.New("ilog: async writer is closed")
+
+// OverflowPolicy controls what AsyncWriter does when its internal queue is full.
+type OverflowPolicy uint8
+
+const (
+	// DropOldest evicts the oldest queued entry to make room for the new one. This is the default.
+	DropOldest OverflowPolicy = iota
+	// DropNewest discards the entry that triggered the overflow, leaving the queue untouched.
+	DropNewest
+	// Block makes Write wait for room in the queue, same as a synchronous writer would.
+	Block
+)
+
+type asyncWriterConfig struct {
+	queueSize int
+	policy    OverflowPolicy
+}
+
+// AsyncWriterOption configures an AsyncWriter built by NewAsyncWriter.
+type AsyncWriterOption func(*asyncWriterConfig)
+
+// WithQueueSize sets the bounded queue capacity. The default is 1024.
+func WithQueueSize(n int) AsyncWriterOption {
+	return func(c *asyncWriterConfig) { c.queueSize = n }
+}
+
+// WithOverflowPolicy sets the behavior when the queue is full. The default is DropOldest.
+func WithOverflowPolicy(policy OverflowPolicy) AsyncWriterOption {
+	return func(c *asyncWriterConfig) { c.policy = policy }
+}
+
+// AsyncWriterStats reports queue activity for observability.
+type AsyncWriterStats struct {
+	Enqueued uint64
+	Dropped  uint64
+}
+
+// AsyncWriter wraps an io.Writer so that Write enqueues its argument onto a bounded MPSC queue
+// instead of blocking on I/O; a dedicated goroutine drains the queue and performs the actual
+// writes, batching adjacent entries into a single inner.Write call to amortize syscalls. Pass it
+// as the writer to NewBuilder to take implLogEntry.logf's final write off the hot path.
+type AsyncWriter struct {
+	inner   io.Writer
+	policy  OverflowPolicy
+	queue   chan []byte
+	closing chan struct{}
+	done    chan struct{}
+	closed  int32
+
+	enqueued uint64
+	dropped  uint64
+}
+
+// NewAsyncWriter starts a drain goroutine writing to inner and returns the AsyncWriter wrapping
+// it. Call Close to stop the goroutine and flush any entries still queued.
+func NewAsyncWriter(inner io.Writer, opts ...AsyncWriterOption) *AsyncWriter {
+	cfg := asyncWriterConfig{
+		queueSize: 1024,
+		policy:    DropOldest,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	w := &AsyncWriter{
+		inner:   inner,
+		policy:  cfg.policy,
+		queue:   make(chan []byte, cfg.queueSize),
+		closing: make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.drain()
+
+	return w
+}
+
+// Write copies p (implLogEntry.logf reuses its buffer via a sync.Pool immediately after Write
+// returns) and enqueues it according to the configured OverflowPolicy.
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&w.closed) == 1 {
+		return 0, ErrAsyncWriterClosed
+	}
+
+	buf := append([]byte(nil), p...)
+
+	select {
+	case w.queue <- buf:
+		atomic.AddUint64(&w.enqueued, 1)
+		return len(p), nil
+	default:
+	}
+
+	switch w.policy {
+	case Block:
+		w.queue <- buf
+		atomic.AddUint64(&w.enqueued, 1)
+	case DropNewest:
+		atomic.AddUint64(&w.dropped, 1)
+	case DropOldest:
+		select {
+		case <-w.queue:
+			atomic.AddUint64(&w.dropped, 1)
+		default:
+		}
+		select {
+		case w.queue <- buf:
+			atomic.AddUint64(&w.enqueued, 1)
+		default:
+			atomic.AddUint64(&w.dropped, 1)
+		}
+	}
+
+	return len(p), nil
+}
+
+// Stats reports the number of entries enqueued and dropped since the AsyncWriter was created.
+func (w *AsyncWriter) Stats() AsyncWriterStats {
+	return AsyncWriterStats{
+		Enqueued: atomic.LoadUint64(&w.enqueued),
+		Dropped:  atomic.LoadUint64(&w.dropped),
+	}
+}
+
+// Close stops accepting new writes and waits for the drain goroutine to flush the remaining
+// queue, or for ctx to be done, whichever happens first.
+func (w *AsyncWriter) Close(ctx context.Context) error {
+	if !atomic.CompareAndSwapInt32(&w.closed, 0, 1) {
+		return nil
+	}
+	close(w.closing)
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("ilog: AsyncWriter.Close: %w", ctx.Err())
+	}
+}
+
+func (w *AsyncWriter) drain() {
+	defer close(w.done)
+
+	for {
+		select {
+		case buf, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			w.writeBatch(buf)
+		case <-w.closing:
+			w.drainRemaining()
+			return
+		}
+	}
+}
+
+func (w *AsyncWriter) drainRemaining() {
+	for {
+		select {
+		case buf, ok := <-w.queue:
+			if !ok {
+				return
+			}
+			w.writeBatch(buf)
+		default:
+			return
+		}
+	}
+}
+
+// writeBatch opportunistically drains any entries already queued behind first and writes them
+// all in a single call to inner.Write, batching adjacent log lines into one syscall.
+func (w *AsyncWriter) writeBatch(first []byte) {
+	batch := first
+	for more := true; more; {
+		select {
+		case buf, ok := <-w.queue:
+			if !ok {
+				more = false
+				break
+			}
+			batch = append(batch, buf...)
+		default:
+			more = false
+		}
+	}
+	_, _ = w.inner.Write(batch)
+}
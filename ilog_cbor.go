@@ -0,0 +1,152 @@
+package ilog
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+)
+
+// Encoder selects the wire format implLogEntry serializes a log event to.
+type Encoder uint8
+
+const (
+	// EncoderJSON renders each log event as a single-line JSON object. This is the default.
+	EncoderJSON Encoder = iota
+	// EncoderCBOR renders each log event as a self-describing CBOR map (major type 5), matching
+	// the same key set as EncoderJSON, to avoid JSON's escaping/quoting overhead when logs are
+	// shipped to a backend that can parse binary. Decode a stream back to JSON with
+	// ilog/cbor.DecodeTo.
+	EncoderCBOR
+)
+
+// CBOR major types, cf. https://www.rfc-editor.org/rfc/rfc8949.html#section-3.
+const (
+	cborMajorUnsigned = 0
+	cborMajorNegative = 1
+	cborMajorBytes    = 2
+	cborMajorText     = 3
+	cborMajorArray    = 4
+	cborMajorMap      = 5
+	cborMajorSimple   = 7
+)
+
+const (
+	cborAdditionalIndefinite = 31
+	cborSimpleFalse          = 20
+	cborSimpleTrue           = 21
+	cborSimpleNull           = 22
+	cborSimpleFloat32        = 26
+	cborSimpleFloat64        = 27
+)
+
+const cborBreak = 0xFF
+
+// appendCBORHead appends a CBOR initial byte (major type + argument) followed by the argument's
+// encoding, mirroring the JSON-side appendKey/appendJSONEscapedString helpers.
+func appendCBORHead(dst []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(dst, major<<5|byte(n))
+	case n <= math.MaxUint8:
+		return append(dst, major<<5|24, byte(n))
+	case n <= math.MaxUint16:
+		return append(dst, major<<5|25, byte(n>>8), byte(n))
+	case n <= math.MaxUint32:
+		return append(dst, major<<5|26, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	default:
+		return append(dst, major<<5|27, byte(n>>56), byte(n>>48), byte(n>>40), byte(n>>32), byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+}
+
+// appendCBORMapStart opens an indefinite-length CBOR map: implLogEntry appends fields
+// incrementally and does not know the final key count up front.
+func appendCBORMapStart(dst []byte) []byte {
+	return append(dst, cborMajorMap<<5|cborAdditionalIndefinite)
+}
+
+func appendCBORMapEnd(dst []byte) []byte {
+	return append(dst, cborBreak)
+}
+
+// appendCBORKey appends a CBOR map key. CBOR needs no trailing ':' or ',' since key/value pairs
+// are simply adjacent items inside the map.
+func appendCBORKey(dst []byte, key string) []byte {
+	return appendCBORTextString(dst, key)
+}
+
+func appendCBORTextString(dst []byte, s string) []byte {
+	dst = appendCBORHead(dst, cborMajorText, uint64(len(s)))
+	return append(dst, s...)
+}
+
+func appendCBORByteString(dst []byte, b []byte) []byte {
+	dst = appendCBORHead(dst, cborMajorBytes, uint64(len(b)))
+	return append(dst, b...)
+}
+
+func appendCBORBool(dst []byte, value bool) []byte {
+	if value {
+		return append(dst, cborMajorSimple<<5|cborSimpleTrue)
+	}
+	return append(dst, cborMajorSimple<<5|cborSimpleFalse)
+}
+
+func appendCBORNull(dst []byte) []byte {
+	return append(dst, cborMajorSimple<<5|cborSimpleNull)
+}
+
+func appendCBORInt(dst []byte, value int64) []byte {
+	if value >= 0 {
+		return appendCBORHead(dst, cborMajorUnsigned, uint64(value))
+	}
+	return appendCBORHead(dst, cborMajorNegative, uint64(-(value + 1)))
+}
+
+func appendCBORUint(dst []byte, value uint64) []byte {
+	return appendCBORHead(dst, cborMajorUnsigned, value)
+}
+
+func appendCBORFloat32(dst []byte, value float32) []byte {
+	dst = append(dst, cborMajorSimple<<5|cborSimpleFloat32)
+	bits := math.Float32bits(value)
+	return append(dst, byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits))
+}
+
+func appendCBORFloat64(dst []byte, value float64) []byte {
+	dst = append(dst, cborMajorSimple<<5|cborSimpleFloat64)
+	bits := math.Float64bits(value)
+	return append(dst,
+		byte(bits>>56), byte(bits>>48), byte(bits>>40), byte(bits>>32),
+		byte(bits>>24), byte(bits>>16), byte(bits>>8), byte(bits))
+}
+
+func appendCBORCaller(dst []byte, callerSkip int, useLongCaller bool) []byte {
+	pc, put := getPCBuffer()
+	defer put()
+
+	var frame runtime.Frame
+	if runtime.Callers(callerSkip, pc.pc) > 0 {
+		frame, _ = runtime.CallersFrames(pc.pc).Next()
+	}
+
+	file := frame.File
+	if !useLongCaller {
+		file = extractShortPath(file)
+	}
+	dst = appendCBORTextString(dst, file+":"+strconv.Itoa(frame.Line))
+
+	return dst
+}
+
+func appendCBORLevelField(dst []byte, level Level) []byte {
+	switch level { //nolint:exhaustive
+	case InfoLevel:
+		return appendCBORTextString(dst, "INFO")
+	case WarnLevel:
+		return appendCBORTextString(dst, "WARNING")
+	case ErrorLevel:
+		return appendCBORTextString(dst, "ERROR")
+	default:
+		return appendCBORTextString(dst, "DEBUG")
+	}
+}
@@ -0,0 +1,51 @@
+package ilog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kunitsuinc/ilog/cbor"
+)
+
+func TestCBOREncodeDecodeRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	logger := NewBuilder(InfoLevel, &buf).AsBinary().Build()
+
+	logger.String("foo", "bar").Int("n", 1).Infof("hello")
+	logger.String("foo", "baz").Int("n", 2).Infof("world")
+
+	var decoded bytes.Buffer
+	if err := cbor.DecodeTo(bytes.NewReader(buf.Bytes()), &decoded); err != nil {
+		t.Fatalf("DecodeTo: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(decoded.String(), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d decoded lines, want 2 (one per event, no stray inter-value bytes): %q", len(lines), decoded.String())
+	}
+
+	for i, want := range []struct {
+		foo string
+		n   float64
+		msg string
+	}{
+		{foo: "bar", n: 1, msg: "hello"},
+		{foo: "baz", n: 2, msg: "world"},
+	} {
+		var event map[string]interface{}
+		if err := json.Unmarshal([]byte(lines[i]), &event); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v (%q)", i, err, lines[i])
+		}
+		if event["foo"] != want.foo {
+			t.Errorf("line %d: foo = %v, want %v", i, event["foo"], want.foo)
+		}
+		if event["n"] != want.n {
+			t.Errorf("line %d: n = %v, want %v", i, event["n"], want.n)
+		}
+		if event["message"] != want.msg {
+			t.Errorf("line %d: message = %v, want %v", i, event["message"], want.msg)
+		}
+	}
+}
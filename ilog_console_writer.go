@@ -0,0 +1,249 @@
+package ilog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"time"
+)
+
+// ANSI color codes for ConsoleWriter's per-level coloring.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiRed    = "\x1b[31m"
+)
+
+type consoleWriterConfig struct {
+	noColor         bool
+	timestampFormat string
+	fieldOrder      []string
+	timestampKey    string
+	levelKey        string
+	callerKey       string
+	messageKey      string
+}
+
+// ConsoleWriterOption configures a ConsoleWriter built by NewConsoleWriter.
+type ConsoleWriterOption func(*consoleWriterConfig)
+
+// WithConsoleNoColor disables ANSI coloring even when the destination is a TTY.
+func WithConsoleNoColor(noColor bool) ConsoleWriterOption {
+	return func(c *consoleWriterConfig) { c.noColor = noColor }
+}
+
+// WithConsoleTimestampFormat sets the time.Format layout the timestamp field is re-rendered
+// with. The default is "2006-01-02T15:04:05".
+func WithConsoleTimestampFormat(layout string) ConsoleWriterOption {
+	return func(c *consoleWriterConfig) { c.timestampFormat = layout }
+}
+
+// WithConsoleFieldOrder lists field keys that should be printed first, in this order; any
+// remaining fields are appended afterward in alphabetical order. Keys absent from an event are
+// skipped.
+func WithConsoleFieldOrder(keys []string) ConsoleWriterOption {
+	return func(c *consoleWriterConfig) { c.fieldOrder = keys }
+}
+
+// WithConsoleKeys overrides the levelKey/timestampKey/callerKey/messageKey ConsoleWriter expects
+// to find in each JSON line; these must match whatever implLoggerConfig.SetLevelKey (and its
+// SetTimestampKey/SetCallerKey/SetMessageKey siblings) was configured with upstream. The default
+// matches NewBuilder's own defaults ("severity", "timestamp", "caller", "message").
+func WithConsoleKeys(levelKey, timestampKey, callerKey, messageKey string) ConsoleWriterOption {
+	return func(c *consoleWriterConfig) {
+		c.levelKey = levelKey
+		c.timestampKey = timestampKey
+		c.callerKey = callerKey
+		c.messageKey = messageKey
+	}
+}
+
+// ConsoleWriter is an io.Writer that parses each JSON log line implLogEntry.logf emits and
+// re-renders it as a colorized, human-readable line:
+//
+//	2006-01-02T15:04:05 INFO  path/to/file.go:42 message key=value key2=value2
+//
+// Pass it as the writer to NewBuilder for developer-friendly local output; it complements, and is
+// not a replacement for, the machine-oriented JSON written in production.
+type ConsoleWriter struct {
+	out   io.Writer
+	cfg   consoleWriterConfig
+	color bool
+}
+
+// NewConsoleWriter returns a ConsoleWriter writing to out. Color is enabled automatically when
+// out is a TTY and disabled when it is piped or redirected, unless overridden by
+// WithConsoleNoColor.
+func NewConsoleWriter(out io.Writer, opts ...ConsoleWriterOption) *ConsoleWriter {
+	cfg := consoleWriterConfig{
+		timestampFormat: "2006-01-02T15:04:05",
+		timestampKey:    "timestamp",
+		levelKey:        "severity",
+		callerKey:       "caller",
+		messageKey:      "message",
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return &ConsoleWriter{
+		out:   out,
+		cfg:   cfg,
+		color: !cfg.noColor && isTerminal(out),
+	}
+}
+
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Write accepts one or more newline-separated JSON log lines (the shape implLogEntry.logf
+// produces with the default separator) and renders each as a human-readable line.
+func (w *ConsoleWriter) Write(p []byte) (int, error) {
+	for _, line := range bytes.Split(bytes.TrimRight(p, "\n"), []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := w.writeLine(line); err != nil {
+			return 0, fmt.Errorf("ilog: ConsoleWriter.Write: %w", err)
+		}
+	}
+	return len(p), nil
+}
+
+func (w *ConsoleWriter) writeLine(line []byte) error {
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(line, &fields); err != nil {
+		// Not a JSON event (e.g. Write called directly with a plain message): pass it through.
+		_, err := fmt.Fprintf(w.out, "%s\n", line)
+		return err //nolint:wrapcheck
+	}
+
+	var buf bytes.Buffer
+
+	if ts, ok := takeString(fields, w.cfg.timestampKey); ok {
+		buf.WriteString(w.formatTimestamp(ts))
+		buf.WriteByte(' ')
+	}
+
+	if level, ok := takeString(fields, w.cfg.levelKey); ok {
+		buf.WriteString(w.formatLevel(level))
+		buf.WriteByte(' ')
+	}
+
+	if caller, ok := takeString(fields, w.cfg.callerKey); ok {
+		buf.WriteString(caller)
+		buf.WriteByte(' ')
+	}
+
+	if msg, ok := takeString(fields, w.cfg.messageKey); ok {
+		buf.WriteString(msg)
+	}
+
+	for _, key := range w.orderedKeys(fields) {
+		buf.WriteByte(' ')
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(formatFieldValue(fields[key]))
+	}
+
+	buf.WriteByte('\n')
+	_, err := w.out.Write(buf.Bytes())
+	return err //nolint:wrapcheck
+}
+
+func takeString(fields map[string]interface{}, key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	v, ok := fields[key]
+	if !ok {
+		return "", false
+	}
+	delete(fields, key)
+	s, ok := v.(string)
+	return s, ok
+}
+
+func (w *ConsoleWriter) formatTimestamp(raw string) string {
+	t, err := time.Parse(time.RFC3339Nano, raw)
+	if err != nil {
+		return raw
+	}
+	return t.Format(w.cfg.timestampFormat)
+}
+
+func (w *ConsoleWriter) formatLevel(level string) string {
+	padded := fmt.Sprintf("%-5s", level)
+	if !w.color {
+		return padded
+	}
+	return levelColor(level) + padded + ansiReset
+}
+
+func levelColor(level string) string {
+	switch level {
+	case "INFO":
+		return ansiGreen
+	case "WARNING":
+		return ansiYellow
+	case "ERROR":
+		return ansiRed
+	default:
+		return ""
+	}
+}
+
+// orderedKeys returns fields' remaining keys: first those named by consoleWriterConfig.fieldOrder,
+// in that order, then everything else sorted alphabetically.
+func (w *ConsoleWriter) orderedKeys(fields map[string]interface{}) []string {
+	seen := make(map[string]bool, len(w.cfg.fieldOrder))
+	ordered := make([]string, 0, len(fields))
+
+	for _, key := range w.cfg.fieldOrder {
+		if _, ok := fields[key]; ok {
+			ordered = append(ordered, key)
+			seen[key] = true
+		}
+	}
+
+	rest := make([]string, 0, len(fields))
+	for key := range fields {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(ordered, rest...)
+}
+
+func formatFieldValue(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case nil:
+		return "null"
+	case float64, bool:
+		return fmt.Sprintf("%v", value)
+	default:
+		// Nested arrays/objects (e.g. Stack frames): fall back to compact JSON.
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Sprintf("%v", value)
+		}
+		return string(b)
+	}
+}
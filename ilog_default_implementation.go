@@ -1,6 +1,7 @@
 package ilog
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"math"
@@ -23,6 +24,11 @@ type implLoggerConfig struct {
 	messageKey      string
 	separator       string
 	writer          io.Writer
+	encoder         Encoder
+	sampler         Sampler
+	stackKey        string
+	stackMarshaler  func(error) []byte
+	hooks           []Hook
 }
 
 type implLogger struct {
@@ -43,6 +49,8 @@ func NewBuilder(level Level, w io.Writer) implLoggerConfig { //nolint:revive
 		messageKey:      "message",
 		separator:       "\n",
 		writer:          w,
+		encoder:         EncoderJSON,
+		stackKey:        "stack",
 	}
 }
 
@@ -86,6 +94,48 @@ func (c implLoggerConfig) SetSeparator(separator string) implLoggerConfig { //no
 	return c
 }
 
+// SetEncoder swaps the wire format implLogEntry serializes to. The default is EncoderJSON.
+func (c implLoggerConfig) SetEncoder(encoder Encoder) implLoggerConfig { //nolint:revive
+	c.encoder = encoder
+	return c
+}
+
+// AsBinary is a shorthand for SetEncoder(EncoderCBOR): it emits a self-describing CBOR map per
+// log event instead of a JSON object, avoiding JSON's escaping/quoting overhead when logs are
+// shipped to a backend that speaks binary.
+func (c implLoggerConfig) AsBinary() implLoggerConfig { //nolint:revive
+	return c.SetEncoder(EncoderCBOR)
+}
+
+// SetSampler installs a Sampler consulted for every event that passes the level filter. A nil
+// sampler (the default) disables sampling entirely and costs nothing on the write path.
+func (c implLoggerConfig) SetSampler(sampler Sampler) implLoggerConfig { //nolint:revive
+	c.sampler = sampler
+	return c
+}
+
+// SetStackKey overrides the field name LogEntry.Stack nests its frame array under. The default is "stack".
+func (c implLoggerConfig) SetStackKey(key string) implLoggerConfig { //nolint:revive
+	c.stackKey = key
+	return c
+}
+
+// SetStackMarshaler installs an extension point that takes over LogEntry.Stack's frame
+// collection entirely: marshaler is called with the error passed to Stack and its return value
+// is embedded verbatim as the stackKey field (with EncoderCBOR, as a CBOR byte string), bypassing
+// the built-in StackTracer/callerser detection.
+func (c implLoggerConfig) SetStackMarshaler(marshaler func(error) []byte) implLoggerConfig { //nolint:revive
+	c.stackMarshaler = marshaler
+	return c
+}
+
+// AddHook registers hook to run, in registration order, on every subsequent logf call that
+// passes the level filter and sampler.
+func (c implLoggerConfig) AddHook(hook Hook) implLoggerConfig { //nolint:revive
+	c.hooks = append(c.hooks[:len(c.hooks):len(c.hooks)], hook)
+	return c
+}
+
 func (c implLoggerConfig) Build() Logger {
 	return &implLogger{
 		config: c,
@@ -138,6 +188,18 @@ func (l *implLogger) ErrWithKey(key string, err error) LogEntry {
 	return l.new().ErrWithKey(key, err)
 }
 
+func (l *implLogger) Stack(err error) LogEntry {
+	return l.new().Stack(err)
+}
+
+// With binds ctx to a new LogEntry so hooks registered via implLoggerConfig.AddHook can recover
+// request-scoped values (trace IDs, request IDs, ...) through the contextCarrier contract.
+func (l *implLogger) With(ctx context.Context) LogEntry {
+	e := l.new()
+	e.ctx = ctx
+	return e
+}
+
 func (l *implLogger) Float32(key string, value float32) LogEntry {
 	return l.new().Float32(key, value)
 }
@@ -219,6 +281,16 @@ type implLogEntry struct {
 	logger      *implLogger
 	bytesBuffer *bytesBuffer
 	put         func()
+	ctx         context.Context
+}
+
+// Context returns the context.Context bound via implLogger.With, or context.Background() if
+// none was bound. It satisfies contextCarrier for hooks such as TraceHook.
+func (e *implLogEntry) Context() context.Context {
+	if e.ctx != nil {
+		return e.ctx
+	}
+	return context.Background()
 }
 
 func (*implLogEntry) Error() string {
@@ -226,6 +298,11 @@ func (*implLogEntry) Error() string {
 }
 
 func (e *implLogEntry) null(key string) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORNull(e.bytesBuffer.bytes)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, null...)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -295,6 +372,11 @@ func (e *implLogEntry) Any(key string, value interface{}) LogEntry {
 }
 
 func (e *implLogEntry) Bool(key string, value bool) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORBool(e.bytesBuffer.bytes, value)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = strconv.AppendBool(e.bytesBuffer.bytes, value)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -302,6 +384,11 @@ func (e *implLogEntry) Bool(key string, value bool) LogEntry {
 }
 
 func (e *implLogEntry) Bytes(key string, value []byte) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORByteString(e.bytesBuffer.bytes, value)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
 	e.bytesBuffer.bytes = appendJSONEscapedString(e.bytesBuffer.bytes, string(value))
@@ -310,6 +397,11 @@ func (e *implLogEntry) Bytes(key string, value []byte) LogEntry {
 }
 
 func (e *implLogEntry) Duration(key string, value time.Duration) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORTextString(e.bytesBuffer.bytes, value.String())
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
 	e.bytesBuffer.bytes = appendJSONEscapedString(e.bytesBuffer.bytes, value.String())
@@ -321,28 +413,38 @@ func (e *implLogEntry) Err(err error) LogEntry {
 	return e.ErrWithKey("error", err)
 }
 
+//nolint:cyclop
 func (e *implLogEntry) ErrWithKey(key string, err error) LogEntry {
-	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	// NOTE: Even if err is your unique error type and nil, it is not judged as nil because it has type information. Calling err.Error() causes panic.
 	// if err != nil {
 	formatter, ok := err.(fmt.Formatter) //nolint:errorlint
+	var s string
 	if ok && formatter != nil {
-		e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
-		e.bytesBuffer.bytes = appendJSONEscapedString(e.bytesBuffer.bytes, fmt.Sprintf("%+v", formatter))
-		e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
+		s = fmt.Sprintf("%+v", formatter)
 	} else {
-		e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
-		e.bytesBuffer.bytes = appendJSONEscapedString(e.bytesBuffer.bytes, err.Error())
-		e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
+		s = err.Error()
 	}
 	// } else {
 	// 	w.bytesBuffer.bytes = append(w.bytesBuffer.bytes, null...)
 	// }
-	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORTextString(e.bytesBuffer.bytes, s)
+		return e
+	}
+	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
+	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
+	e.bytesBuffer.bytes = appendJSONEscapedString(e.bytesBuffer.bytes, s)
+	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"', ',')
 	return e
 }
 
 func (e *implLogEntry) Float32(key string, value float32) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORFloat32(e.bytesBuffer.bytes, value)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = appendFloatFieldValue(e.bytesBuffer.bytes, float64(value), 32)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -350,6 +452,11 @@ func (e *implLogEntry) Float32(key string, value float32) LogEntry {
 }
 
 func (e *implLogEntry) Float64(key string, value float64) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORFloat64(e.bytesBuffer.bytes, value)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = appendFloatFieldValue(e.bytesBuffer.bytes, value, 64)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -357,6 +464,11 @@ func (e *implLogEntry) Float64(key string, value float64) LogEntry {
 }
 
 func (e *implLogEntry) Int(key string, value int) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORInt(e.bytesBuffer.bytes, int64(value))
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = strconv.AppendInt(e.bytesBuffer.bytes, int64(value), 10)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -364,6 +476,11 @@ func (e *implLogEntry) Int(key string, value int) LogEntry {
 }
 
 func (e *implLogEntry) Int32(key string, value int32) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORInt(e.bytesBuffer.bytes, int64(value))
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = strconv.AppendInt(e.bytesBuffer.bytes, int64(value), 10)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -371,6 +488,11 @@ func (e *implLogEntry) Int32(key string, value int32) LogEntry {
 }
 
 func (e *implLogEntry) Int64(key string, value int64) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORInt(e.bytesBuffer.bytes, value)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = strconv.AppendInt(e.bytesBuffer.bytes, value, 10)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -378,6 +500,11 @@ func (e *implLogEntry) Int64(key string, value int64) LogEntry {
 }
 
 func (e *implLogEntry) String(key string, value string) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORTextString(e.bytesBuffer.bytes, value)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
 	e.bytesBuffer.bytes = appendJSONEscapedString(e.bytesBuffer.bytes, value)
@@ -386,14 +513,25 @@ func (e *implLogEntry) String(key string, value string) LogEntry {
 }
 
 func (e *implLogEntry) Time(key string, value time.Time) LogEntry {
+	formatted := value.Format(e.logger.config.timestampFormat)
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORTextString(e.bytesBuffer.bytes, formatted)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"')
-	e.bytesBuffer.bytes = appendJSONEscapedString(e.bytesBuffer.bytes, value.Format(e.logger.config.timestampFormat))
+	e.bytesBuffer.bytes = appendJSONEscapedString(e.bytesBuffer.bytes, formatted)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, '"', ',')
 	return e
 }
 
 func (e *implLogEntry) Uint(key string, value uint) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORUint(e.bytesBuffer.bytes, uint64(value))
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = strconv.AppendUint(e.bytesBuffer.bytes, uint64(value), 10)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -401,6 +539,11 @@ func (e *implLogEntry) Uint(key string, value uint) LogEntry {
 }
 
 func (e *implLogEntry) Uint32(key string, value uint32) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORUint(e.bytesBuffer.bytes, uint64(value))
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = strconv.AppendUint(e.bytesBuffer.bytes, uint64(value), 10)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -408,6 +551,11 @@ func (e *implLogEntry) Uint32(key string, value uint32) LogEntry {
 }
 
 func (e *implLogEntry) Uint64(key string, value uint64) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORUint(e.bytesBuffer.bytes, value)
+		return e
+	}
 	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
 	e.bytesBuffer.bytes = strconv.AppendUint(e.bytesBuffer.bytes, value, 10)
 	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
@@ -454,38 +602,73 @@ func (e *implLogEntry) logf(level Level, format string, args ...interface{}) err
 	if level < e.logger.config.level {
 		return nil
 	}
+	if e.logger.config.sampler != nil && !e.logger.config.sampler.Sample(level) {
+		return nil
+	}
+
+	message := format
+	if len(args) > 0 {
+		message = fmt.Sprintf(format, args...)
+	}
+
+	for _, hook := range e.logger.config.hooks {
+		hook.Run(e, level, message)
+	}
 
 	b, put := getBytesBuffer()
 	defer put()
 
-	b.bytes = append(b.bytes, '{')
+	cbor := e.logger.config.encoder == EncoderCBOR
+
+	if cbor {
+		b.bytes = appendCBORMapStart(b.bytes)
+	} else {
+		b.bytes = append(b.bytes, '{')
+	}
 
 	if len(e.logger.config.levelKey) > 0 {
-		b.bytes = appendKey(b.bytes, e.logger.config.levelKey)
-		b.bytes = appendLevelField(b.bytes, level)
-		b.bytes = append(b.bytes, ',')
+		if cbor {
+			b.bytes = appendCBORKey(b.bytes, e.logger.config.levelKey)
+			b.bytes = appendCBORLevelField(b.bytes, level)
+		} else {
+			b.bytes = appendKey(b.bytes, e.logger.config.levelKey)
+			b.bytes = appendLevelField(b.bytes, level)
+			b.bytes = append(b.bytes, ',')
+		}
 	}
 	if len(e.logger.config.timestampKey) > 0 {
-		b.bytes = appendKey(b.bytes, e.logger.config.timestampKey)
-		b.bytes = append(b.bytes, '"')
-		b.bytes = appendJSONEscapedString(b.bytes, time.Now().In(e.logger.config.timestampZone).Format(e.logger.config.timestampFormat))
-		b.bytes = append(b.bytes, '"', ',')
+		timestamp := time.Now().In(e.logger.config.timestampZone).Format(e.logger.config.timestampFormat)
+		if cbor {
+			b.bytes = appendCBORKey(b.bytes, e.logger.config.timestampKey)
+			b.bytes = appendCBORTextString(b.bytes, timestamp)
+		} else {
+			b.bytes = appendKey(b.bytes, e.logger.config.timestampKey)
+			b.bytes = append(b.bytes, '"')
+			b.bytes = appendJSONEscapedString(b.bytes, timestamp)
+			b.bytes = append(b.bytes, '"', ',')
+		}
 	}
 	if len(e.logger.config.callerKey) > 0 {
-		b.bytes = appendKey(b.bytes, e.logger.config.callerKey)
-		b.bytes = append(b.bytes, '"')
-		b.bytes = appendCaller(b.bytes, e.logger.config.callerSkip, e.logger.config.useLongCaller)
-		b.bytes = append(b.bytes, '"', ',')
+		if cbor {
+			b.bytes = appendCBORKey(b.bytes, e.logger.config.callerKey)
+			b.bytes = appendCBORCaller(b.bytes, e.logger.config.callerSkip, e.logger.config.useLongCaller)
+		} else {
+			b.bytes = appendKey(b.bytes, e.logger.config.callerKey)
+			b.bytes = append(b.bytes, '"')
+			b.bytes = appendCaller(b.bytes, e.logger.config.callerSkip, e.logger.config.useLongCaller)
+			b.bytes = append(b.bytes, '"', ',')
+		}
 	}
 	if len(e.logger.config.messageKey) > 0 {
-		b.bytes = appendKey(b.bytes, e.logger.config.messageKey)
-		b.bytes = append(b.bytes, '"')
-		if len(args) > 0 {
-			b.bytes = appendJSONEscapedString(b.bytes, fmt.Sprintf(format, args...))
+		if cbor {
+			b.bytes = appendCBORKey(b.bytes, e.logger.config.messageKey)
+			b.bytes = appendCBORTextString(b.bytes, message)
 		} else {
-			b.bytes = appendJSONEscapedString(b.bytes, format)
+			b.bytes = appendKey(b.bytes, e.logger.config.messageKey)
+			b.bytes = append(b.bytes, '"')
+			b.bytes = appendJSONEscapedString(b.bytes, message)
+			b.bytes = append(b.bytes, '"', ',')
 		}
-		b.bytes = append(b.bytes, '"', ',')
 	}
 
 	if len(e.logger.fields) > 0 {
@@ -496,13 +679,22 @@ func (e *implLogEntry) logf(level Level, format string, args ...interface{}) err
 		b.bytes = append(b.bytes, e.bytesBuffer.bytes...)
 	}
 
-	if b.bytes[len(b.bytes)-1] == ',' {
+	if cbor {
+		b.bytes = appendCBORMapEnd(b.bytes)
+	} else if b.bytes[len(b.bytes)-1] == ',' {
 		b.bytes[len(b.bytes)-1] = '}'
 	} else {
 		b.bytes = append(b.bytes, '}')
 	}
 
-	if _, err := e.logger.config.writer.Write(append(b.bytes, e.logger.config.separator...)); err != nil {
+	// CBOR values are self-delimiting, so appending config.separator between them (meant for
+	// human/line-oriented JSON output) would be read back as a stray standalone value by
+	// cbor.DecodeTo; skip it regardless of how EncoderCBOR was selected.
+	separator := e.logger.config.separator
+	if cbor {
+		separator = ""
+	}
+	if _, err := e.logger.config.writer.Write(append(b.bytes, separator...)); err != nil {
 		err = fmt.Errorf("w.logger.writer.Write: p=%s: %w", b.bytes, err)
 		defer Global().Errorf(err.Error())
 		return err
@@ -656,4 +848,4 @@ func appendLevelField(dst []byte, level Level) []byte {
 	default:
 		return append(dst, `"DEBUG"`...)
 	}
-}
\ No newline at end of file
+}
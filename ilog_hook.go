@@ -0,0 +1,18 @@
+package ilog
+
+import "context"
+
+// Hook is a pluggable, cross-cutting extension point invoked by implLogEntry.logf for every
+// event that passes the level filter and Sampler, after both but before the event buffer is
+// flushed to the writer. Run typically calls back into e (e.g. e.String(...)) to inject
+// additional fields — metrics counters, trace/span IDs, error mirroring to an external service.
+type Hook interface {
+	Run(e LogEntry, level Level, msg string)
+}
+
+// contextCarrier is implemented by every implLogEntry; hooks that need request-scoped data
+// type-assert for it to recover the context.Context bound via implLogger.With
+// (context.Background() when none was bound).
+type contextCarrier interface {
+	Context() context.Context
+}
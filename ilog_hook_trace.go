@@ -0,0 +1,23 @@
+package ilog
+
+import "go.opentelemetry.io/otel/trace"
+
+// TraceHook appends trace_id and span_id fields sourced from the OpenTelemetry span on the
+// context bound via implLogger.With. Events logged without a bound context, or without an active
+// span, are left untouched.
+type TraceHook struct{}
+
+func (TraceHook) Run(e LogEntry, _ Level, _ string) {
+	carrier, ok := e.(contextCarrier)
+	if !ok {
+		return
+	}
+
+	spanContext := trace.SpanContextFromContext(carrier.Context())
+	if !spanContext.IsValid() {
+		return
+	}
+
+	e.String("trace_id", spanContext.TraceID().String())
+	e.String("span_id", spanContext.SpanID().String())
+}
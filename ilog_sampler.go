@@ -0,0 +1,138 @@
+package ilog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplerStats reports how many events a Sampler has let through versus dropped, for
+// observability.
+type SamplerStats struct {
+	Sampled uint64
+	Dropped uint64
+}
+
+// Sampler decides whether a log event at level should be written. It is consulted by
+// implLogEntry.logf after the level filter (so rejected events never reach serialization) but
+// before the event is built, via implLoggerConfig.SetSampler.
+type Sampler interface {
+	Sample(level Level) bool
+	Stats() SamplerStats
+}
+
+type ratioSampler struct {
+	n       uint64
+	counter uint64
+	sampled uint64
+	dropped uint64
+}
+
+// NewRatioSampler returns a Sampler that keeps 1 event out of every n, regardless of level.
+func NewRatioSampler(n int) Sampler {
+	if n < 1 {
+		n = 1
+	}
+	return &ratioSampler{n: uint64(n)}
+}
+
+func (s *ratioSampler) Sample(Level) bool {
+	c := atomic.AddUint64(&s.counter, 1)
+	if (c-1)%s.n == 0 {
+		atomic.AddUint64(&s.sampled, 1)
+		return true
+	}
+	atomic.AddUint64(&s.dropped, 1)
+	return false
+}
+
+func (s *ratioSampler) Stats() SamplerStats {
+	return SamplerStats{
+		Sampled: atomic.LoadUint64(&s.sampled),
+		Dropped: atomic.LoadUint64(&s.dropped),
+	}
+}
+
+type burstWindow struct {
+	resetAt time.Time
+	count   uint64
+}
+
+type burstSampler struct {
+	n        uint64
+	interval time.Duration
+	mu       sync.Mutex
+	windows  map[Level]*burstWindow
+	sampled  uint64
+	dropped  uint64
+}
+
+// NewBurstSampler returns a Sampler that allows the first n events per interval, tracked
+// independently per Level, and drops the rest of the window.
+func NewBurstSampler(n int, interval time.Duration) Sampler {
+	if n < 1 {
+		n = 1
+	}
+	return &burstSampler{
+		n:        uint64(n),
+		interval: interval,
+		windows:  make(map[Level]*burstWindow),
+	}
+}
+
+func (s *burstSampler) Sample(level Level) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	w, ok := s.windows[level]
+	if !ok || now.After(w.resetAt) {
+		w = &burstWindow{resetAt: now.Add(s.interval)}
+		s.windows[level] = w
+	}
+	w.count++
+
+	if w.count <= s.n {
+		atomic.AddUint64(&s.sampled, 1)
+		return true
+	}
+	atomic.AddUint64(&s.dropped, 1)
+	return false
+}
+
+func (s *burstSampler) Stats() SamplerStats {
+	return SamplerStats{
+		Sampled: atomic.LoadUint64(&s.sampled),
+		Dropped: atomic.LoadUint64(&s.dropped),
+	}
+}
+
+type levelSampler struct {
+	byLevel map[Level]Sampler
+}
+
+// NewLevelSampler returns a Sampler that dispatches to a different Sampler per Level. Levels
+// absent from byLevel are never sampled out.
+func NewLevelSampler(byLevel map[Level]Sampler) Sampler {
+	return &levelSampler{byLevel: byLevel}
+}
+
+func (s *levelSampler) Sample(level Level) bool {
+	sampler, ok := s.byLevel[level]
+	if !ok {
+		return true
+	}
+	return sampler.Sample(level)
+}
+
+// Stats sums the Stats of the per-level samplers in byLevel. Events at levels absent from byLevel
+// pass through without being counted.
+func (s *levelSampler) Stats() SamplerStats {
+	var total SamplerStats
+	for _, sampler := range s.byLevel {
+		st := sampler.Stats()
+		total.Sampled += st.Sampled
+		total.Dropped += st.Dropped
+	}
+	return total
+}
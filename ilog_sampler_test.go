@@ -0,0 +1,102 @@
+package ilog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRatioSampler(t *testing.T) {
+	s := NewRatioSampler(3)
+
+	var sampled int
+	for i := 0; i < 9; i++ {
+		if s.Sample(InfoLevel) {
+			sampled++
+		}
+	}
+
+	if want := 3; sampled != want {
+		t.Fatalf("sampled = %d, want %d", sampled, want)
+	}
+
+	stats := s.Stats()
+	if stats.Sampled != 3 || stats.Dropped != 6 {
+		t.Fatalf("Stats() = %+v, want {Sampled:3 Dropped:6}", stats)
+	}
+}
+
+func TestRatioSamplerClampsBelowOne(t *testing.T) {
+	s := NewRatioSampler(0)
+	for i := 0; i < 5; i++ {
+		if !s.Sample(InfoLevel) {
+			t.Fatalf("NewRatioSampler(0) dropped event %d, want every event kept", i)
+		}
+	}
+}
+
+func TestBurstSamplerAllowsNPerInterval(t *testing.T) {
+	s := NewBurstSampler(2, time.Hour)
+
+	if !s.Sample(InfoLevel) || !s.Sample(InfoLevel) {
+		t.Fatal("expected the first 2 events within the burst to be sampled")
+	}
+	if s.Sample(InfoLevel) {
+		t.Fatal("expected the 3rd event within the same window to be dropped")
+	}
+
+	stats := s.Stats()
+	if stats.Sampled != 2 || stats.Dropped != 1 {
+		t.Fatalf("Stats() = %+v, want {Sampled:2 Dropped:1}", stats)
+	}
+}
+
+func TestBurstSamplerTracksLevelsIndependently(t *testing.T) {
+	s := NewBurstSampler(1, time.Hour)
+
+	if !s.Sample(InfoLevel) {
+		t.Fatal("expected first INFO event to be sampled")
+	}
+	if s.Sample(InfoLevel) {
+		t.Fatal("expected second INFO event to be dropped")
+	}
+	if !s.Sample(ErrorLevel) {
+		t.Fatal("expected first ERROR event to be sampled despite INFO's window being exhausted")
+	}
+}
+
+func TestBurstSamplerResetsAfterInterval(t *testing.T) {
+	s := NewBurstSampler(1, 10*time.Millisecond)
+
+	if !s.Sample(InfoLevel) {
+		t.Fatal("expected first event to be sampled")
+	}
+	if s.Sample(InfoLevel) {
+		t.Fatal("expected second event within the window to be dropped")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	if !s.Sample(InfoLevel) {
+		t.Fatal("expected the window to have reset after the interval elapsed")
+	}
+}
+
+func TestLevelSamplerDispatchesPerLevel(t *testing.T) {
+	s := NewLevelSampler(map[Level]Sampler{
+		InfoLevel: NewRatioSampler(2),
+	})
+
+	if !s.Sample(InfoLevel) {
+		t.Fatal("expected first INFO event through the ratio-2 sampler to be sampled")
+	}
+	if s.Sample(InfoLevel) {
+		t.Fatal("expected second INFO event to be dropped")
+	}
+
+	// ErrorLevel has no entry in byLevel, so it must always pass through.
+	for i := 0; i < 5; i++ {
+		if !s.Sample(ErrorLevel) {
+			t.Fatalf("ErrorLevel event %d dropped, want levels absent from byLevel to never be sampled out", i)
+		}
+	}
+}
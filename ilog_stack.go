@@ -0,0 +1,172 @@
+package ilog
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"strconv"
+)
+
+// Frame is one resolved call-stack entry rendered by LogEntry.Stack.
+type Frame struct {
+	Func string
+	File string
+	Line int
+}
+
+// StackTracer is implemented by errors that carry their own captured call stack already resolved
+// into []Frame.
+type StackTracer interface {
+	StackTrace() []Frame
+}
+
+// callerser is implemented by errors that instead captured a raw program-counter slice (e.g. via
+// runtime.Callers at the point the error was created); Stack resolves these through
+// runtime.CallersFrames.
+type callerser interface {
+	Callers() []uintptr
+}
+
+// Stack walks err's chain via errors.Unwrap looking for the first error that exposes its call
+// stack (through StackTracer or callerser) and emits the resolved frames as a nested array under
+// the configured stackKey. If config.stackMarshaler is set, it takes over entirely and its
+// return value is embedded as the stackKey field instead. If no frames are found, Stack is a
+// no-op.
+func (e *implLogEntry) Stack(err error) LogEntry {
+	if err == nil {
+		return e
+	}
+
+	if marshaler := e.logger.config.stackMarshaler; marshaler != nil {
+		return e.rawField(e.logger.config.stackKey, marshaler(err))
+	}
+
+	frames := collectFrames(err)
+	if frames == nil {
+		return e
+	}
+
+	return e.frames(e.logger.config.stackKey, frames)
+}
+
+func collectFrames(err error) []Frame {
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if tracer, ok := current.(StackTracer); ok {
+			return tracer.StackTrace()
+		}
+		if c, ok := current.(callerser); ok {
+			return framesFromPC(c.Callers())
+		}
+		if frames, ok := pkgErrorsFrames(current); ok {
+			return frames
+		}
+	}
+	return nil
+}
+
+// pkgErrorsFrames duck-types github.com/pkg/errors' `interface { StackTrace() errors.StackTrace }`
+// without importing that package: pkg/errors defines `type Frame uintptr` and
+// `type StackTrace []Frame`, with each Frame storing its program counter plus one. Matching that
+// shape via reflection (a no-arg method named StackTrace returning a slice whose element kind is
+// uintptr) lets Stack interoperate with real pkg/errors errors while keeping ilog dependency-free.
+func pkgErrorsFrames(err error) ([]Frame, bool) {
+	method := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !method.IsValid() || method.Type().NumIn() != 0 || method.Type().NumOut() != 1 {
+		return nil, false
+	}
+
+	stack := method.Call(nil)[0]
+	if stack.Kind() != reflect.Slice || stack.Type().Elem().Kind() != reflect.Uintptr {
+		return nil, false
+	}
+
+	pcs := make([]uintptr, stack.Len())
+	for i := 0; i < stack.Len(); i++ {
+		// pkg/errors' Frame.pc subtracts 1 from the stored value; framesFromPC expects raw PCs.
+		pcs[i] = uintptr(stack.Index(i).Uint()) - 1
+	}
+	return framesFromPC(pcs), true
+}
+
+func framesFromPC(pcs []uintptr) []Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+
+	frames := make([]Frame, 0, len(pcs))
+	iter := runtime.CallersFrames(pcs)
+	for {
+		frame, more := iter.Next()
+		frames = append(frames, Frame{Func: frame.Function, File: frame.File, Line: frame.Line})
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// rawField embeds a pre-encoded value verbatim after key, bypassing the type-dispatched
+// appendJSON*/appendCBOR* helpers; with EncoderCBOR the value is wrapped in a CBOR byte string.
+// Used by SetStackMarshaler to let callers fully control the encoding of the stack field.
+func (e *implLogEntry) rawField(key string, raw []byte) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORByteString(e.bytesBuffer.bytes, raw)
+		return e
+	}
+	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
+	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, raw...)
+	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
+	return e
+}
+
+func (e *implLogEntry) frames(key string, frames []Frame) LogEntry {
+	if e.logger.config.encoder == EncoderCBOR {
+		e.bytesBuffer.bytes = appendCBORKey(e.bytesBuffer.bytes, key)
+		e.bytesBuffer.bytes = appendCBORFrames(e.bytesBuffer.bytes, frames)
+		return e
+	}
+	e.bytesBuffer.bytes = appendKey(e.bytesBuffer.bytes, key)
+	e.bytesBuffer.bytes = appendJSONFrames(e.bytesBuffer.bytes, frames)
+	e.bytesBuffer.bytes = append(e.bytesBuffer.bytes, ',')
+	return e
+}
+
+func appendJSONFrames(dst []byte, frames []Frame) []byte {
+	dst = append(dst, '[')
+	for i, frame := range frames {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, '{')
+		dst = appendKey(dst, "func")
+		dst = append(dst, '"')
+		dst = appendJSONEscapedString(dst, frame.Func)
+		dst = append(dst, '"', ',')
+		dst = appendKey(dst, "file")
+		dst = append(dst, '"')
+		dst = appendJSONEscapedString(dst, frame.File)
+		dst = append(dst, '"', ',')
+		dst = appendKey(dst, "line")
+		dst = strconv.AppendInt(dst, int64(frame.Line), 10)
+		dst = append(dst, '}')
+	}
+	dst = append(dst, ']')
+	return dst
+}
+
+func appendCBORFrames(dst []byte, frames []Frame) []byte {
+	dst = append(dst, cborMajorArray<<5|cborAdditionalIndefinite)
+	for _, frame := range frames {
+		dst = appendCBORMapStart(dst)
+		dst = appendCBORKey(dst, "func")
+		dst = appendCBORTextString(dst, frame.Func)
+		dst = appendCBORKey(dst, "file")
+		dst = appendCBORTextString(dst, frame.File)
+		dst = appendCBORKey(dst, "line")
+		dst = appendCBORInt(dst, int64(frame.Line))
+		dst = appendCBORMapEnd(dst)
+	}
+	dst = append(dst, cborBreak) // close the indefinite-length array
+	return dst
+}
@@ -0,0 +1,72 @@
+package ilog
+
+import (
+	"errors"
+	"time"
+)
+
+type Level int
+
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+type LogEntry interface {
+	Any(key string, value interface{}) LogEntry
+	Bool(key string, value bool) LogEntry
+	Bytes(key string, value []byte) LogEntry
+	Duration(key string, value time.Duration) LogEntry
+	Err(err error) LogEntry
+	ErrWithKey(key string, err error) LogEntry
+	Float32(key string, value float32) LogEntry
+	Float64(key string, value float64) LogEntry
+	Int(key string, value int) LogEntry
+	Int32(key string, value int32) LogEntry
+	Int64(key string, value int64) LogEntry
+	String(key, value string) LogEntry
+	Uint(key string, value uint) LogEntry
+	Uint32(key string, value uint32) LogEntry
+	Uint64(key string, value uint64) LogEntry
+	Logger() Logger
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Logf(level Level, format string, args ...interface{})
+	Write(p []byte) (int, error)
+	error
+}
+
+type Logger interface {
+	Level() Level
+	SetLevel(level Level) Logger
+	AddCallerSkip(skip int) Logger
+	Copy() Logger
+	Any(key string, value interface{}) LogEntry
+	Bool(key string, value bool) LogEntry
+	Bytes(key string, value []byte) LogEntry
+	Err(err error) LogEntry
+	ErrWithKey(key string, err error) LogEntry
+	Float32(key string, value float32) LogEntry
+	Float64(key string, value float64) LogEntry
+	Int(key string, value int) LogEntry
+	Int32(key string, value int32) LogEntry
+	Int64(key string, value int64) LogEntry
+	String(key, value string) LogEntry
+	Uint(key string, value uint) LogEntry
+	Uint32(key string, value uint32) LogEntry
+	Uint64(key string, value uint64) LogEntry
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+	Logf(level Level, format string, args ...interface{})
+	Write(p []byte) (int, error)
+}
+
+var ErrLogEntryIsNotWritten = errors.New("ilog: log entry is not written")
+
+func Global() Logger { return nil }
@@ -0,0 +1,372 @@
+// Package rotate provides an io.Writer suitable for passing to ilog.NewBuilder that rotates the
+// underlying file by size and/or time, optionally compresses rotated files, and prunes them by
+// count and age.
+package rotate
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// Hourly rotates the file once per hour.
+	Hourly = time.Hour
+	// Daily rotates the file once per day.
+	Daily = 24 * time.Hour
+)
+
+type config struct {
+	maxBytes       int64
+	rotateInterval time.Duration
+	compress       bool
+	maxBackups     int
+	maxAge         time.Duration
+}
+
+// Option configures a Writer built by New.
+type Option func(*config)
+
+// WithMaxBytes rotates the file once it would exceed n bytes. 0 (the default) disables
+// size-based rotation.
+func WithMaxBytes(n int64) Option {
+	return func(c *config) { c.maxBytes = n }
+}
+
+// WithRotateInterval rotates the file once interval has elapsed since the Writer was created or
+// last rotated on interval (size-triggered rotations do not reset it), e.g. rotate.Hourly or
+// rotate.Daily. 0 (the default) disables time-based rotation. The elapsed
+// interval is what drives rotation; the pattern's strftime verbs only control the rotated file's
+// name, so e.g. a "%Y%m%d" pattern with WithRotateInterval(Hourly) still rotates hourly, with
+// successive files in the same day distinguished by the ".1", ".2", ... sequence suffix.
+func WithRotateInterval(interval time.Duration) Option {
+	return func(c *config) { c.rotateInterval = interval }
+}
+
+// WithCompress gzip-compresses a file once it is rotated out, removing the uncompressed copy.
+func WithCompress(compress bool) Option {
+	return func(c *config) { c.compress = compress }
+}
+
+// WithMaxBackups keeps at most n rotated files (0, the default, keeps them all).
+func WithMaxBackups(n int) Option {
+	return func(c *config) { c.maxBackups = n }
+}
+
+// WithMaxAge removes rotated files older than d (0, the default, never removes by age).
+func WithMaxAge(d time.Duration) Option {
+	return func(c *config) { c.maxAge = d }
+}
+
+// Writer is an io.Writer that rotates the file it writes to by size and/or time. It is safe for
+// concurrent Write calls. Rotation renames/opens the new file synchronously so log order is
+// preserved, but compression and retention pruning of the old file run on a background goroutine
+// so Write is never blocked on them.
+type Writer struct {
+	pattern string
+	cfg     config
+
+	mu         sync.Mutex
+	file       *os.File
+	path       string
+	size       int64 // atomic
+	nextRotate time.Time
+
+	wg sync.WaitGroup
+}
+
+// New returns a Writer that writes to the strftime-style pattern (e.g. "/var/log/app-%Y%m%d.log"),
+// expanded against the current time. With a pattern that has no time verbs the live file keeps the
+// same path, and files rotated out of it (by size or interval) are suffixed ".1", ".2", and so on.
+func New(pattern string, opts ...Option) (*Writer, error) {
+	cfg := config{}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	w := &Writer{pattern: pattern, cfg: cfg}
+	if err := w.openLocked(formatStrftime(pattern, time.Now())); err != nil {
+		return nil, fmt.Errorf("rotate: New: %w", err)
+	}
+	if cfg.rotateInterval > 0 {
+		w.nextRotate = time.Now().Add(cfg.rotateInterval)
+	}
+	return w, nil
+}
+
+// Write writes p to the current file, rotating first if p would push the file past MaxBytes or
+// if RotateInterval has elapsed since the last interval rotation (or since New).
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.rotateIfNeededLocked(len(p)); err != nil {
+		return 0, fmt.Errorf("rotate: Write: %w", err)
+	}
+
+	n, err := w.file.Write(p)
+	atomic.AddInt64(&w.size, int64(n))
+	if err != nil {
+		return n, fmt.Errorf("rotate: Write: %w", err)
+	}
+	return n, nil
+}
+
+func (w *Writer) rotateIfNeededLocked(nextWrite int) error {
+	now := time.Now()
+	sizeExceeded := w.cfg.maxBytes > 0 && atomic.LoadInt64(&w.size)+int64(nextWrite) > w.cfg.maxBytes
+	intervalElapsed := w.cfg.rotateInterval > 0 && !now.Before(w.nextRotate)
+
+	if !sizeExceeded && !intervalElapsed {
+		return nil
+	}
+
+	if err := w.rotateLocked(now); err != nil {
+		return err
+	}
+
+	if intervalElapsed {
+		// Only advance the schedule when the interval itself elapsed, not on every rotation: a
+		// size-triggered rotation in between must not push the next interval deadline back out,
+		// or frequent size rotation would starve the interval guarantee indefinitely.
+		w.nextRotate = now.Add(w.cfg.rotateInterval)
+	}
+	return nil
+}
+
+// rotateLocked rotates the file being written at time now. The live file always ends up at
+// basePath, the strftime-expanded pattern for now: if the pattern already changed basePath since
+// the file was opened (e.g. a day boundary with a "%Y%m%d" pattern), the old file is simply left
+// at its own distinct name. Otherwise (a static pattern, or RotateInterval firing more often than
+// the pattern's own verbs resolve) basePath is still occupied by the file currently being written,
+// so that file is renamed out of the way to a fresh numbered backup name before the new base file
+// is opened — the live path must never be a suffixed name, or the next rotation would open the
+// base name again and silently resume appending to what is meant to be a retired backup.
+func (w *Writer) rotateLocked(now time.Time) error {
+	basePath := formatStrftime(w.pattern, now)
+	old, oldPath := w.file, w.path
+
+	finalizePath := oldPath
+	if old != nil && oldPath == basePath {
+		backupPath, err := nextBackupPath(basePath)
+		if err != nil {
+			return fmt.Errorf("nextBackupPath: %w", err)
+		}
+		if err := os.Rename(oldPath, backupPath); err != nil {
+			return fmt.Errorf("os.Rename: %w", err)
+		}
+		finalizePath = backupPath
+	}
+
+	if err := w.openLocked(basePath); err != nil {
+		return err
+	}
+	currentPath := w.path // snapshot under w.mu: finalize/prune run on another goroutine
+
+	if old != nil {
+		w.wg.Add(1)
+		go w.finalize(old, finalizePath, currentPath)
+	}
+	return nil
+}
+
+// nextBackupPath returns basePath suffixed with the next unused ".N" sequence number, determined
+// by scanning existing on-disk backups (including already-compressed ".gz" ones) rather than an
+// in-memory counter, so numbering survives process restarts and never collides with a backup
+// that's still on disk.
+func nextBackupPath(basePath string) (string, error) {
+	matches, err := filepath.Glob(basePath + ".*")
+	if err != nil {
+		return "", fmt.Errorf("filepath.Glob: %w", err)
+	}
+
+	max := 0
+	for _, match := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(match, basePath+"."), ".gz")
+		n, err := strconv.Atoi(suffix)
+		if err != nil {
+			continue
+		}
+		if n > max {
+			max = n
+		}
+	}
+
+	return fmt.Sprintf("%s.%d", basePath, max+1), nil
+}
+
+func (w *Writer) openLocked(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("os.MkdirAll: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("f.Stat: %w", err)
+	}
+
+	w.file = f
+	w.path = path
+	atomic.StoreInt64(&w.size, info.Size())
+	return nil
+}
+
+// finalize closes a rotated-out file, optionally gzip-compresses it, and prunes old files per
+// MaxBackups/MaxAge. It runs on its own goroutine so rotateLocked (and therefore Write) never
+// blocks on it. currentPath is a snapshot of w.path taken under w.mu by the caller, since w.path
+// itself may be rewritten by a concurrent rotation while this goroutine runs.
+func (w *Writer) finalize(f *os.File, path, currentPath string) {
+	defer w.wg.Done()
+	defer func() { _ = f.Close() }()
+
+	if w.cfg.compress {
+		if err := compressFile(path); err == nil {
+			path += ".gz"
+		}
+	}
+
+	w.prune(currentPath)
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("os.Open: %w", err)
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("os.OpenFile: %w", err)
+	}
+	defer func() { _ = dst.Close() }()
+
+	gw := gzip.NewWriter(dst)
+	if _, err := io.Copy(gw, src); err != nil {
+		return fmt.Errorf("io.Copy: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("gw.Close: %w", err)
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("os.Remove: %w", err)
+	}
+	return nil
+}
+
+// prune removes rotated files matching pattern's glob beyond MaxBackups and/or older than
+// MaxAge. currentPath (the file currently being written to) is never removed.
+func (w *Writer) prune(currentPath string) {
+	if w.cfg.maxBackups <= 0 && w.cfg.maxAge <= 0 {
+		return
+	}
+
+	matches, err := filepath.Glob(globPattern(w.pattern))
+	if err != nil {
+		return
+	}
+
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+
+	candidates := make([]candidate, 0, len(matches))
+	for _, match := range matches {
+		if match == currentPath {
+			continue
+		}
+		info, err := os.Stat(match)
+		if err != nil {
+			continue
+		}
+		candidates = append(candidates, candidate{path: match, modTime: info.ModTime()})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].modTime.After(candidates[j].modTime)
+	})
+
+	now := time.Now()
+	for i, c := range candidates {
+		expiredByAge := w.cfg.maxAge > 0 && now.Sub(c.modTime) > w.cfg.maxAge
+		expiredByCount := w.cfg.maxBackups > 0 && i >= w.cfg.maxBackups
+		if expiredByAge || expiredByCount {
+			_ = os.Remove(c.path)
+		}
+	}
+}
+
+// Reopen closes and reopens the current file at its existing path, re-creating it if an external
+// tool (logrotate, a container sidecar) has already moved it out from under the Writer. It is
+// meant to be called from a SIGHUP handler.
+func (w *Writer) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.file != nil {
+		_ = w.file.Close()
+	}
+	if err := w.openLocked(w.path); err != nil {
+		return fmt.Errorf("rotate: Reopen: %w", err)
+	}
+	return nil
+}
+
+// Close closes the current file and waits for any in-flight background compression/pruning to
+// finish.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	err := w.file.Close()
+	w.mu.Unlock()
+
+	w.wg.Wait()
+	if err != nil {
+		return fmt.Errorf("rotate: Close: %w", err)
+	}
+	return nil
+}
+
+// formatStrftime expands the %Y/%m/%d/%H/%M/%S verbs in pattern against t. Unlike handing the
+// whole pattern to time.Format as a layout string, this only touches the verbs themselves, so a
+// path containing digits that happen to look like Go reference-time fragments (e.g. "...-001/")
+// is never misinterpreted.
+func formatStrftime(pattern string, t time.Time) string {
+	replacer := strings.NewReplacer(
+		"%Y", fmt.Sprintf("%04d", t.Year()),
+		"%m", fmt.Sprintf("%02d", int(t.Month())),
+		"%d", fmt.Sprintf("%02d", t.Day()),
+		"%H", fmt.Sprintf("%02d", t.Hour()),
+		"%M", fmt.Sprintf("%02d", t.Minute()),
+		"%S", fmt.Sprintf("%02d", t.Second()),
+	)
+	return replacer.Replace(pattern)
+}
+
+var strftimeGlobReplacer = strings.NewReplacer(
+	"%Y", "*",
+	"%m", "*",
+	"%d", "*",
+	"%H", "*",
+	"%M", "*",
+	"%S", "*",
+)
+
+func globPattern(pattern string) string {
+	return strftimeGlobReplacer.Replace(pattern) + "*"
+}
@@ -0,0 +1,47 @@
+package rotate
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestWriteConcurrentRotation drives many goroutines writing concurrently while MaxBytes forces
+// frequent rotation, with compression and MaxBackups pruning both enabled so finalize/prune run
+// concurrently with Write. Run with -race: it catches unsynchronized access to Writer's internal
+// path/file state (see rotateLocked's currentPath snapshot).
+func TestWriteConcurrentRotation(t *testing.T) {
+	dir := t.TempDir()
+	w, err := New(filepath.Join(dir, "app.log"), WithMaxBytes(64), WithMaxBackups(3), WithCompress(true))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	defer w.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := w.Write([]byte("0123456789abcdef\n")); err != nil {
+					t.Errorf("Write: %v", err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) == 0 {
+		t.Fatal("expected rotation to have produced at least one file")
+	}
+}